@@ -0,0 +1,146 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/casibase/casibase/object"
+)
+
+// GetWebfinger
+// @Title GetWebfinger
+// @Tag ActivityPub API
+// @Description resolve a WebFinger resource to its ActivityPub actor
+// @Param resource query string true "The acct: resource, e.g. acct:chat@example.com"
+// @Success 200 {object} controllers.Response The Response object
+// @router /.well-known/webfinger [get]
+func (c *ApiController) GetWebfinger() {
+	resource := c.Input().Get("resource")
+	owner, name, err := parseWebfingerResource(resource)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	origin := getOriginFromHost(c.Ctx.Request.Host)
+	actorId := object.GetActorId(origin, owner, name)
+
+	c.Data["json"] = map[string]interface{}{
+		"subject": resource,
+		"links": []map[string]string{
+			{
+				"rel":  "self",
+				"type": "application/activity+json",
+				"href": actorId,
+			},
+		},
+	}
+	c.ServeJSON()
+}
+
+// parseWebfingerResource extracts the owner/name pair encoded as the local
+// part of an "acct:name@owner" resource.
+func parseWebfingerResource(resource string) (string, string, error) {
+	resource = strings.TrimPrefix(resource, "acct:")
+	parts := strings.SplitN(resource, "@", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("activitypub: invalid resource: %s", resource)
+	}
+
+	return parts[1], parts[0], nil
+}
+
+// GetActor
+// @Title GetActor
+// @Tag ActivityPub API
+// @Description get the ActivityStreams actor document for an organization or public chat
+// @Param owner query string true "The owner of the actor"
+// @Param name query string true "The name of the actor"
+// @Success 200 {object} object.Actor The Response object
+// @router /actors/:owner/:name [get]
+func (c *ApiController) GetActor() {
+	owner := c.Ctx.Input.Param(":owner")
+	name := c.Ctx.Input.Param(":name")
+
+	origin := getOriginFromHost(c.Ctx.Request.Host)
+	actor, err := object.GetActor(origin, owner, name)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = actor
+	c.ServeJSON()
+}
+
+// HandleInbox
+// @Title HandleInbox
+// @Tag ActivityPub API
+// @Description accept an inbound ActivityPub activity (Create, Follow, Undo Follow, Delete)
+// @Param owner query string true "The owner of the local actor"
+// @Param name query string true "The name of the local actor"
+// @Param body body object.Activity true "The inbound activity"
+// @Success 200 {object} controllers.Response The Response object
+// @router /actors/:owner/:name/inbox [post]
+func (c *ApiController) HandleInbox() {
+	owner := c.Ctx.Input.Param(":owner")
+	name := c.Ctx.Input.Param(":name")
+
+	var activity object.Activity
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &activity)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	remoteActorKey := ""
+	if remoteUser, rErr := object.GetRemoteUserByActorIdAny(activity.Actor); rErr == nil && remoteUser != nil {
+		remoteActorKey = remoteUser.PublicKeyPem
+	}
+	if remoteActorKey == "" {
+		remoteActorKey, err = object.GetRemoteActorPublicKeyPem(activity.Actor)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	}
+	// An actor whose public key we cannot resolve (unreachable, erroring, or
+	// malformed actor document) must not be treated as verified: that would
+	// let anyone spoof Follow/Create/Delete/Undo activities for an actor URL
+	// we simply failed to reach.
+	if remoteActorKey == "" {
+		c.ResponseError(fmt.Sprintf("activitypub: could not resolve public key for actor: %s", activity.Actor))
+		return
+	}
+
+	err = object.VerifyInboundSignature(c.Ctx.Request, c.Ctx.Input.RequestBody, remoteActorKey)
+	if err != nil {
+		c.ResponseError(fmt.Sprintf("activitypub: signature verification failed: %s", err.Error()))
+		return
+	}
+
+	origin := getOriginFromHost(c.Ctx.Request.Host)
+	localActor := object.GetActorId(origin, owner, name)
+	err = object.HandleInboxActivity(localActor, &activity)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -18,7 +18,9 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/beego/beego/v2/core/logs"
 	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/object/rtm"
 	"github.com/casibase/casibase/util"
 )
 
@@ -26,7 +28,7 @@ import (
 // @Title GetGlobalMessages
 // @Tag Message API
 // @Description get global messages
-// @Success 200 {array} object.Message The Response object
+// @Success 200 {array} controllers.MessageWithDestructInfo The Response object
 // @router /get-global-messages [get]
 func (c *ApiController) GetGlobalMessages() {
 	messages, err := object.GetGlobalMessages()
@@ -35,7 +37,67 @@ func (c *ApiController) GetGlobalMessages() {
 		return
 	}
 
-	c.ResponseOk(messages)
+	err = refineMessageAttachments(messages)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	result, err := withDestructInfoAll(messages)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(result)
+}
+
+// refineMessageAttachments rewrites every message's generalized
+// {bucket,key,contentType,size,etag} FileName into a signed GET URL at read
+// time, the same way RefineMessageFiles already does for legacy local files.
+func refineMessageAttachments(messages []*object.Message) error {
+	for _, message := range messages {
+		fileName, err := object.RefineAttachmentFileName(message.FileName)
+		if err != nil {
+			return err
+		}
+		message.FileName = fileName
+	}
+
+	return nil
+}
+
+// MessageWithDestructInfo augments object.Message with the ephemeral
+// destruction fields read paths need to render the ephemeral-message UI.
+// These live in the separate MessageDestruct table (see
+// object.ScheduleMessageDestruction) rather than as columns on Message
+// itself, so they're attached here instead of being part of object.Message.
+type MessageWithDestructInfo struct {
+	*object.Message
+	IsMsgDestruct bool   `json:"isMsgDestruct"`
+	DestructAt    string `json:"destructAt,omitempty"`
+}
+
+func withDestructInfo(message *object.Message) (*MessageWithDestructInfo, error) {
+	isMsgDestruct, destructAt, err := object.GetMessageDestructInfo(message.Owner, message.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MessageWithDestructInfo{Message: message, IsMsgDestruct: isMsgDestruct, DestructAt: destructAt}, nil
+}
+
+func withDestructInfoAll(messages []*object.Message) ([]*MessageWithDestructInfo, error) {
+	result := make([]*MessageWithDestructInfo, 0, len(messages))
+	for _, message := range messages {
+		withInfo, err := withDestructInfo(message)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, withInfo)
+	}
+
+	return result, nil
 }
 
 // GetMessages
@@ -44,12 +106,14 @@ func (c *ApiController) GetGlobalMessages() {
 // @Description get Messages
 // @Param user query string true "The user of message"
 // @Param chat query string true "The chat of message"
-// @Success 200 {array} object.Message The Response object
+// @Param excludeExpired query bool false "Whether to exclude ephemeral messages that are past their destruct time but not yet swept"
+// @Success 200 {array} controllers.MessageWithDestructInfo The Response object
 // @router /get-Messages [get]
 func (c *ApiController) GetMessages() {
 	user := c.Input().Get("user")
 	chat := c.Input().Get("chat")
 	selectedUser := c.Input().Get("selectedUser")
+	excludeExpired := c.Input().Get("excludeExpired") == "true"
 
 	if c.IsAdmin() {
 		user = ""
@@ -70,7 +134,24 @@ func (c *ApiController) GetMessages() {
 			c.ResponseError(err.Error())
 			return
 		}
-		c.ResponseOk(messages)
+		if excludeExpired {
+			messages, err = filterExpiredMessages(messages)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+		}
+		err = refineMessageAttachments(messages)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		result, err := withDestructInfoAll(messages)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		c.ResponseOk(result)
 		return
 	}
 
@@ -80,7 +161,44 @@ func (c *ApiController) GetMessages() {
 		return
 	}
 
-	c.ResponseOk(messages)
+	if excludeExpired {
+		messages, err = filterExpiredMessages(messages)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	}
+
+	err = refineMessageAttachments(messages)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	result, err := withDestructInfoAll(messages)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(result)
+}
+
+// filterExpiredMessages drops messages whose ephemeral destruct time has
+// already passed but that the background sweeper hasn't deleted yet.
+func filterExpiredMessages(messages []*object.Message) ([]*object.Message, error) {
+	result := make([]*object.Message, 0, len(messages))
+	for _, message := range messages {
+		expired, err := object.IsMessageExpired(message.Owner, message.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !expired {
+			result = append(result, message)
+		}
+	}
+
+	return result, nil
 }
 
 // GetMessage
@@ -88,7 +206,7 @@ func (c *ApiController) GetMessages() {
 // @Tag Message API
 // @Description get message
 // @Param id query string true "The id of message"
-// @Success 200 {object} object.Message The Response object
+// @Success 200 {object} controllers.MessageWithDestructInfo The Response object
 // @router /get-message [get]
 func (c *ApiController) GetMessage() {
 	id := c.Input().Get("id")
@@ -98,8 +216,24 @@ func (c *ApiController) GetMessage() {
 		c.ResponseError(err.Error())
 		return
 	}
+	if message == nil {
+		c.ResponseOk(message)
+		return
+	}
 
-	c.ResponseOk(message)
+	message.FileName, err = object.RefineAttachmentFileName(message.FileName)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	result, err := withDestructInfo(message)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(result)
 }
 
 // UpdateMessage
@@ -136,6 +270,23 @@ func (c *ApiController) UpdateMessage() {
 		return
 	}
 
+	if success {
+		rtm.PublishMessageUpdated(message.Chat, message)
+
+		// The message update already succeeded: a failure to (un)schedule its
+		// ephemeral destruction is a side effect, not a reason to tell the
+		// client the whole request failed and risk a client-side retry.
+		destructParams := getMessageDestructParams(c.Ctx.Input.RequestBody)
+		if destructParams.IsMsgDestruct {
+			err = object.ScheduleMessageDestruction(&message, destructParams.DestructAfterSeconds)
+		} else {
+			err = object.CancelMessageDestruction(message.Owner, message.Name)
+		}
+		if err != nil {
+			logs.Error("UpdateMessage: failed to update message destruction schedule for %s/%s: %v", message.Owner, message.Name, err)
+		}
+	}
+
 	c.ResponseOk(success)
 }
 
@@ -225,6 +376,8 @@ func (c *ApiController) AddMessage() {
 	}
 
 	if success {
+		rtm.PublishMessageAdded(message.Chat, message)
+
 		chatId := util.GetId(message.Owner, message.Chat)
 		chat, err = object.GetChat(chatId)
 		if err != nil {
@@ -250,6 +403,8 @@ func (c *ApiController) AddMessage() {
 				c.ResponseError(err.Error())
 				return
 			}
+
+			rtm.PublishMessageAdded(message.Chat, answerMessage)
 		}
 		if chat != nil && chat.Type == "Signal" {
 			result := object.IMMessage{
@@ -258,11 +413,61 @@ func (c *ApiController) AddMessage() {
 			jsonData, _ := json.Marshal(result)
 			object.IMController.SendMessageToChat(*chat, string(jsonData))
 		}
+
+		// The message is already persisted at this point: federation and
+		// ephemeral-destruction scheduling are best-effort side effects, so a
+		// failure here must not make AddMessage report failure for a message
+		// that exists (that would invite client-side retries and duplicates).
+		if isPublicMessage(c.Ctx.Input.RequestBody) {
+			origin := getOriginFromHost(c.Ctx.Request.Host)
+			err = object.FederateMessage(origin, &message)
+			if err != nil {
+				logs.Error("AddMessage: failed to federate message %s/%s: %v", message.Owner, message.Name, err)
+			}
+		}
+
+		destructParams := getMessageDestructParams(c.Ctx.Input.RequestBody)
+		if destructParams.IsMsgDestruct {
+			err = object.ScheduleMessageDestruction(&message, destructParams.DestructAfterSeconds)
+			if err != nil {
+				logs.Error("AddMessage: failed to schedule message destruction for %s/%s: %v", message.Owner, message.Name, err)
+			}
+		}
 	}
 
 	c.ResponseOk(chat)
 }
 
+// messageDestructParams is decoded separately from object.Message since
+// IsMsgDestruct/DestructAfterSeconds are only relevant to the ephemeral
+// message feature and aren't otherwise part of object.Message.
+type messageDestructParams struct {
+	IsMsgDestruct        bool `json:"isMsgDestruct"`
+	DestructAfterSeconds int  `json:"destructAfterSeconds"`
+}
+
+func getMessageDestructParams(requestBody []byte) messageDestructParams {
+	var params messageDestructParams
+	_ = json.Unmarshal(requestBody, &params)
+	return params
+}
+
+// isPublicMessage reports whether the client marked this message as
+// federatable, e.g. {"isPublic": true} alongside the usual object.Message
+// fields. It is decoded separately since the flag only matters to
+// ActivityPub federation and is not otherwise part of object.Message.
+func isPublicMessage(requestBody []byte) bool {
+	var params struct {
+		IsPublic bool `json:"isPublic"`
+	}
+	err := json.Unmarshal(requestBody, &params)
+	if err != nil {
+		return false
+	}
+
+	return params.IsPublic
+}
+
 // DeleteMessage
 // @Title DeleteMessage
 // @Tag Message API
@@ -289,6 +494,19 @@ func (c *ApiController) DeleteMessage() {
 		return
 	}
 
+	if success {
+		rtm.PublishMessageDeleted(message.Chat, message)
+
+		// The message is already deleted: a failure to notify federated
+		// followers is a side effect and must not make DeleteMessage report
+		// failure for a message that no longer exists.
+		origin := getOriginFromHost(c.Ctx.Request.Host)
+		err = object.FederateMessageDelete(origin, &message)
+		if err != nil {
+			logs.Error("DeleteMessage: failed to federate deletion of message %s/%s: %v", message.Owner, message.Name, err)
+		}
+	}
+
 	c.ResponseOk(success)
 }
 
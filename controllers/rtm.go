@@ -0,0 +1,86 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/object/rtm"
+	"github.com/gorilla/websocket"
+)
+
+var rtmUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// GetRtmToken
+// @Title GetRtmToken
+// @Tag RTM API
+// @Description issue a short-lived token used to authenticate a websocket upgrade when no session cookie is available
+// @Success 200 {object} controllers.Response The Response object
+// @router /get-rtm-token [get]
+func (c *ApiController) GetRtmToken() {
+	user := c.GetSessionUsername()
+	if user == "" {
+		c.ResponseError("Please login first")
+		return
+	}
+
+	c.ResponseOk(object.GetRtmToken(user))
+}
+
+// ConnectMessageStream
+// @Title ConnectMessageStream
+// @Tag RTM API
+// @Description upgrade to a websocket and stream message.added/message.updated/message.deleted and presence events for a chat
+// @Param chat query string true "The chat to subscribe to"
+// @Param token query string false "A short-lived token from GetRtmToken, required when there is no session cookie"
+// @router /connect-message-stream [get]
+func (c *ApiController) ConnectMessageStream() {
+	chat := c.Input().Get("chat")
+	token := c.Input().Get("token")
+
+	user := c.GetSessionUsername()
+	if user == "" && token != "" {
+		user = object.VerifyRtmToken(token)
+	}
+	if user == "" {
+		c.ResponseError("Please login first")
+		return
+	}
+
+	if !c.IsAdmin() {
+		ownedChat, err := object.GetChat(chat)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		if ownedChat == nil || ownedChat.User != user {
+			c.ResponseError("You can only subscribe to your own chat")
+			return
+		}
+	}
+
+	conn, err := rtmUpgrader.Upgrade(c.Ctx.ResponseWriter, c.Ctx.Request, nil)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	rtm.DefaultHub().Register(conn, user, chat)
+}
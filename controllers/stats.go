@@ -0,0 +1,134 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/object/stats"
+)
+
+// GetMessageStats
+// @Title GetMessageStats
+// @Tag Stats API
+// @Description get time-bucketed message counts and token counts (avgLatencyMs is not computed and is always 0)
+// @Param granularity query string true "hour, day, week or month"
+// @Param startDate query string true "The inclusive start date, YYYY-MM-DD"
+// @Param endDate query string true "The inclusive end date, YYYY-MM-DD"
+// @Param organization query string false "Filter by organization"
+// @Param chatType query string false "Filter by chat.Type"
+// @Param format query string false "\"csv\" to download a CSV export instead of JSON"
+// @Success 200 {array} stats.Bucket The Response object
+// @router /get-message-stats [get]
+func (c *ApiController) GetMessageStats() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	granularity := stats.Granularity(c.Input().Get("granularity"))
+	startDate := c.Input().Get("startDate")
+	endDate := c.Input().Get("endDate")
+	organization := c.Input().Get("organization")
+	chatType := c.Input().Get("chatType")
+
+	buckets, err := object.GetMessageStats(granularity, startDate, endDate, organization, chatType)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.respondStats(buckets, "message-stats")
+}
+
+// GetActiveUsers
+// @Title GetActiveUsers
+// @Tag Stats API
+// @Description get time-bucketed unique active user counts (DAU/WAU/MAU depending on granularity)
+// @Param granularity query string true "hour, day, week or month"
+// @Param startDate query string true "The inclusive start date, YYYY-MM-DD"
+// @Param endDate query string true "The inclusive end date, YYYY-MM-DD"
+// @Param organization query string false "Filter by organization"
+// @Param format query string false "\"csv\" to download a CSV export instead of JSON"
+// @Success 200 {array} stats.Bucket The Response object
+// @router /get-active-users [get]
+func (c *ApiController) GetActiveUsers() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	granularity := stats.Granularity(c.Input().Get("granularity"))
+	startDate := c.Input().Get("startDate")
+	endDate := c.Input().Get("endDate")
+	organization := c.Input().Get("organization")
+
+	buckets, err := object.GetActiveUsers(granularity, startDate, endDate, organization)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.respondStats(buckets, "active-users")
+}
+
+// GetRegistrationStats
+// @Title GetRegistrationStats
+// @Tag Stats API
+// @Description get time-bucketed new user registration counts
+// @Param granularity query string true "hour, day, week or month"
+// @Param startDate query string true "The inclusive start date, YYYY-MM-DD"
+// @Param endDate query string true "The inclusive end date, YYYY-MM-DD"
+// @Param organization query string false "Filter by organization"
+// @Param format query string false "\"csv\" to download a CSV export instead of JSON"
+// @Success 200 {array} stats.Bucket The Response object
+// @router /get-registration-stats [get]
+func (c *ApiController) GetRegistrationStats() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	granularity := stats.Granularity(c.Input().Get("granularity"))
+	startDate := c.Input().Get("startDate")
+	endDate := c.Input().Get("endDate")
+	organization := c.Input().Get("organization")
+
+	buckets, err := object.GetRegistrationStats(granularity, startDate, endDate, organization)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.respondStats(buckets, "registration-stats")
+}
+
+// respondStats writes either a JSON or a CSV response for a stats endpoint,
+// depending on the "format" query parameter.
+func (c *ApiController) respondStats(buckets []*stats.Bucket, fileNamePrefix string) {
+	if c.Input().Get("format") != "csv" {
+		c.ResponseOk(buckets)
+		return
+	}
+
+	csvBytes, err := stats.ToCsv(buckets)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/csv")
+	c.Ctx.Output.Header("Content-Disposition", "attachment; filename=\""+fileNamePrefix+".csv\"")
+	c.Ctx.Output.Body(csvBytes)
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"strings"
+
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/object/storage"
+)
+
+// requireOwnedAttachmentKey checks that the caller is logged in and that key
+// falls under their own attachment namespace ("<user>/..."), the same way
+// GetMessages scopes reads to the caller's own user unless they are an
+// admin. It writes an error response and returns ok=false when the check
+// fails.
+func (c *ApiController) requireOwnedAttachmentKey(key string) (user string, ok bool) {
+	user = c.GetSessionUsername()
+	if user == "" {
+		c.ResponseError("Please login first")
+		return "", false
+	}
+
+	if !isOwnedAttachmentKey(user, c.IsAdmin(), key) {
+		c.ResponseError("You can only access your own attachments")
+		return "", false
+	}
+
+	return user, true
+}
+
+// isOwnedAttachmentKey reports whether key is namespaced under user's own
+// attachments, or the caller is an admin and may access any key.
+func isOwnedAttachmentKey(user string, isAdmin bool, key string) bool {
+	return isAdmin || strings.HasPrefix(key, user+"/")
+}
+
+// GetPresignedPutUrl
+// @Title GetPresignedPutUrl
+// @Tag Storage API
+// @Description get a presigned URL the frontend can upload a message attachment to directly
+// @Param key query string true "The object key the attachment will be stored under, must be namespaced under the caller's own user"
+// @Param contentType query string true "The MIME type of the attachment"
+// @Success 200 {object} controllers.Response The Response object
+// @router /get-presigned-put-url [get]
+func (c *ApiController) GetPresignedPutUrl() {
+	key := c.Input().Get("key")
+	contentType := c.Input().Get("contentType")
+
+	_, ok := c.requireOwnedAttachmentKey(key)
+	if !ok {
+		return
+	}
+
+	store, err := object.GetAttachmentObjectStore()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	url, err := store.GetPresignedPutUrl(key, contentType, storage.DefaultPresignExpiry)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(map[string]string{
+		"url":    url,
+		"bucket": store.Bucket(),
+		"key":    key,
+	})
+}
+
+// GetPresignedGetUrl
+// @Title GetPresignedGetUrl
+// @Tag Storage API
+// @Description get a presigned URL the frontend can download a message attachment from directly
+// @Param key query string true "The object key of the attachment, must be namespaced under the caller's own user"
+// @Success 200 {object} controllers.Response The Response object
+// @router /get-presigned-get-url [get]
+func (c *ApiController) GetPresignedGetUrl() {
+	key := c.Input().Get("key")
+
+	_, ok := c.requireOwnedAttachmentKey(key)
+	if !ok {
+		return
+	}
+
+	store, err := object.GetAttachmentObjectStore()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	url, err := store.GetPresignedGetUrl(key, storage.DefaultPresignExpiry)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(map[string]string{
+		"url": url,
+	})
+}
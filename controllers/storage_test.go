@@ -0,0 +1,40 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import "testing"
+
+func TestIsOwnedAttachmentKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		isAdmin bool
+		key     string
+		want    bool
+	}{
+		{name: "own key", user: "alice", key: "alice/chat1/file.png", want: true},
+		{name: "other user's key", user: "alice", key: "bob/chat1/file.png", want: false},
+		{name: "prefix collision without separator", user: "al", key: "alice/chat1/file.png", want: false},
+		{name: "admin may access any key", user: "alice", isAdmin: true, key: "bob/chat1/file.png", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOwnedAttachmentKey(tt.user, tt.isAdmin, tt.key); got != tt.want {
+				t.Errorf("isOwnedAttachmentKey(%q, %v, %q) = %v, want %v", tt.user, tt.isAdmin, tt.key, got, tt.want)
+			}
+		})
+	}
+}
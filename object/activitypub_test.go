@@ -0,0 +1,106 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signTestRequest(t *testing.T, req *http.Request, privateKey *rsa.PrivateKey, body []byte) {
+	t.Helper()
+
+	digest := sha256.Sum256(body)
+	req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]))
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+	signingString := "(request-target): post " + req.URL.Path + "\nhost: " + req.Host + "\ndate: " + req.Header.Get("Date") + "\ndigest: " + req.Header.Get("Digest")
+	hashed := sha256.Sum256([]byte(signingString))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("failed to sign test request: %v", err)
+	}
+
+	req.Header.Set("Signature", `keyId="test#main-key",algorithm="rsa-sha256",headers="(request-target) host date digest",signature="`+base64.StdEncoding.EncodeToString(signature)+`"`)
+}
+
+func testActorKeyPair(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate test key: %v", err)
+	}
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal test public key: %v", err)
+	}
+	publicKeyPem := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}))
+
+	return privateKey, publicKeyPem
+}
+
+func TestVerifyInboundSignatureAcceptsValidRequest(t *testing.T) {
+	privateKey, publicKeyPem := testActorKeyPair(t)
+	body := []byte(`{"type":"Follow","actor":"https://example.com/actors/a/b"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/actors/a/b/inbox", nil)
+	signTestRequest(t, req, privateKey, body)
+
+	if err := VerifyInboundSignature(req, body, publicKeyPem); err != nil {
+		t.Fatalf("expected valid signature to verify, got error: %v", err)
+	}
+}
+
+func TestVerifyInboundSignatureRejectsTamperedBody(t *testing.T) {
+	privateKey, publicKeyPem := testActorKeyPair(t)
+	originalBody := []byte(`{"type":"Follow","actor":"https://example.com/actors/a/b"}`)
+	tamperedBody := []byte(`{"type":"Delete","actor":"https://example.com/actors/a/b"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/actors/a/b/inbox", nil)
+	signTestRequest(t, req, privateKey, originalBody)
+
+	// Replay the original Digest/Signature/Date headers with a swapped-in
+	// body: the Digest header no longer matches the actual payload, so
+	// verification must fail instead of trusting the stale signature.
+	err := VerifyInboundSignature(req, tamperedBody, publicKeyPem)
+	if err == nil {
+		t.Fatal("expected verification to fail for a body that doesn't match the Digest header")
+	}
+}
+
+func TestVerifyInboundSignatureRejectsBadSignature(t *testing.T) {
+	privateKey, _ := testActorKeyPair(t)
+	_, otherPublicKeyPem := testActorKeyPair(t)
+	body := []byte(`{"type":"Follow","actor":"https://example.com/actors/a/b"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/actors/a/b/inbox", nil)
+	signTestRequest(t, req, privateKey, body)
+
+	err := VerifyInboundSignature(req, body, otherPublicKeyPem)
+	if err == nil {
+		t.Fatal("expected verification to fail against the wrong public key")
+	}
+}
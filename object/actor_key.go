@@ -0,0 +1,105 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/casibase/casibase/util"
+)
+
+// ActorKey holds the RSA key pair used to sign outbound ActivityPub
+// deliveries for a given organization or public chat actor.
+type ActorKey struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	PrivateKeyPem string `xorm:"mediumtext" json:"-"`
+	PublicKeyPem  string `xorm:"mediumtext" json:"publicKeyPem"`
+}
+
+func getActorKey(owner string, name string) (*ActorKey, error) {
+	actorKey := ActorKey{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&actorKey)
+	if err != nil {
+		return nil, err
+	}
+	if existed {
+		return &actorKey, nil
+	}
+
+	return generateActorKey(owner, name)
+}
+
+// generateActorKey creates and persists a new 2048-bit RSA key pair for an
+// actor the first time it is needed.
+func generateActorKey(owner string, name string) (*ActorKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKeyPem := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}))
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyPem := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicKeyBytes,
+	}))
+
+	actorKey := &ActorKey{
+		Owner:         owner,
+		Name:          name,
+		CreatedTime:   util.GetCurrentTime(),
+		PrivateKeyPem: privateKeyPem,
+		PublicKeyPem:  publicKeyPem,
+	}
+
+	_, err = adapter.engine.Insert(actorKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return actorKey, nil
+}
+
+func getActorPublicKeyPem(owner string, name string) (string, error) {
+	actorKey, err := getActorKey(owner, name)
+	if err != nil {
+		return "", err
+	}
+
+	return actorKey.PublicKeyPem, nil
+}
+
+func getActorPrivateKey(owner string, name string) (*rsa.PrivateKey, error) {
+	actorKey, err := getActorKey(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(actorKey.PrivateKeyPem))
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
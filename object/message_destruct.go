@@ -0,0 +1,235 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/casibase/casibase/object/storage"
+	"github.com/casibase/casibase/util"
+	"xorm.io/core"
+)
+
+const destructSweepInterval = 30 * time.Second
+
+// MessageDestruct tracks the ephemeral lifetime of a message. It is kept in
+// its own table, keyed by the message's owner/name, rather than as columns
+// on Message, so a message can be scheduled for destruction without every
+// read path having to know about the feature.
+type MessageDestruct struct {
+	Owner      string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name       string `xorm:"varchar(100) notnull pk" json:"name"`
+	DestructAt string `xorm:"varchar(100) index" json:"destructAt"`
+	IsSwept    bool   `xorm:"bool" json:"isSwept"`
+}
+
+// ScheduleMessageDestruction records that message should be deleted
+// destructAfterSeconds after it was created, computing and persisting
+// DestructAt from message.CreatedTime.
+func ScheduleMessageDestruction(message *Message, destructAfterSeconds int) error {
+	createdTime, err := time.Parse(time.RFC3339, message.CreatedTime)
+	if err != nil {
+		createdTime = time.Now()
+	}
+	destructAt := createdTime.Add(time.Duration(destructAfterSeconds) * time.Second)
+
+	destruct := &MessageDestruct{
+		Owner:      message.Owner,
+		Name:       message.Name,
+		DestructAt: destructAt.Format(time.RFC3339),
+	}
+
+	existing := MessageDestruct{Owner: message.Owner, Name: message.Name}
+	existed, err := adapter.engine.Get(&existing)
+	if err != nil {
+		return err
+	}
+	if existed {
+		_, err = adapter.engine.ID(core.PK{message.Owner, message.Name}).Cols("destruct_at", "is_swept").Update(destruct)
+		return err
+	}
+
+	_, err = adapter.engine.Insert(destruct)
+	return err
+}
+
+// CancelMessageDestruction removes any pending destruction schedule for a
+// message, e.g. when IsMsgDestruct is turned off in UpdateMessage.
+func CancelMessageDestruction(owner string, name string) error {
+	_, err := adapter.engine.ID(core.PK{owner, name}).Delete(&MessageDestruct{})
+	return err
+}
+
+// GetMessageDestructInfo returns whether a message has a pending (not yet
+// swept) destruction schedule and, if so, when it will be destroyed. Read
+// paths use this to expose ephemeral status to clients, since
+// IsMsgDestruct/DestructAt live in MessageDestruct rather than as columns
+// on Message itself.
+func GetMessageDestructInfo(owner string, name string) (isMsgDestruct bool, destructAt string, err error) {
+	destruct := MessageDestruct{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&destruct)
+	if err != nil || !existed || destruct.IsSwept {
+		return false, "", err
+	}
+
+	return true, destruct.DestructAt, nil
+}
+
+// IsMessageExpired reports whether a message has a destruction schedule that
+// has already elapsed, regardless of whether the sweeper has run yet.
+func IsMessageExpired(owner string, name string) (bool, error) {
+	destruct := MessageDestruct{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&destruct)
+	if err != nil || !existed {
+		return false, err
+	}
+
+	destructAt, err := time.Parse(time.RFC3339, destruct.DestructAt)
+	if err != nil {
+		return false, err
+	}
+
+	return !destructAt.After(time.Now()), nil
+}
+
+// getExpiredMessageDestructs returns every not-yet-swept schedule whose
+// DestructAt has elapsed.
+func getExpiredMessageDestructs() ([]*MessageDestruct, error) {
+	destructs := []*MessageDestruct{}
+	err := adapter.engine.Where("is_swept = ? AND destruct_at <= ?", false, time.Now().Format(time.RFC3339)).Find(&destructs)
+	if err != nil {
+		return nil, err
+	}
+
+	return destructs, nil
+}
+
+// sweepExpiredMessages deletes every message (and its attachment, if any)
+// whose destruction schedule has elapsed, then marks the schedule swept.
+func sweepExpiredMessages() {
+	destructs, err := getExpiredMessageDestructs()
+	if err != nil {
+		return
+	}
+
+	for _, destruct := range destructs {
+		message, err := GetMessage(destruct.GetId())
+		if err != nil {
+			continue
+		}
+		if message == nil {
+			// The message is already gone, e.g. deleted directly through
+			// DeleteMessage without going through this sweeper. Without
+			// removing the schedule here it would keep matching
+			// is_swept = false forever and accumulate as a zombie row.
+			adapter.engine.ID(core.PK{destruct.Owner, destruct.Name}).Delete(&MessageDestruct{})
+			continue
+		}
+
+		deleteMessageAttachment(message)
+
+		_, err = DeleteMessage(message)
+		if err != nil {
+			continue
+		}
+
+		destruct.IsSwept = true
+		adapter.engine.ID(core.PK{destruct.Owner, destruct.Name}).Cols("is_swept").Update(destruct)
+	}
+}
+
+func deleteMessageAttachment(message *Message) {
+	if message.FileName == "" {
+		return
+	}
+
+	var fileInfo storage.FileInfo
+	err := json.Unmarshal([]byte(message.FileName), &fileInfo)
+	if err != nil || fileInfo.Key == "" {
+		return
+	}
+
+	store, err := GetAttachmentObjectStore()
+	if err != nil {
+		return
+	}
+
+	_ = store.Delete(fileInfo.Key)
+}
+
+func (destruct *MessageDestruct) GetId() string {
+	return util.GetId(destruct.Owner, destruct.Name)
+}
+
+const sweeperLockName = "message-destruct-sweeper"
+
+// SweeperLock is a single DB row used as a lease so that only one node in a
+// horizontally scaled deployment runs the destruction sweeper at a time.
+type SweeperLock struct {
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	HolderId    string `xorm:"varchar(100)" json:"holderId"`
+	LeaseExpiry string `xorm:"varchar(100)" json:"leaseExpiry"`
+}
+
+// tryAcquireSweeperLease attempts to become (or remain) the leader for the
+// destruction sweeper by updating the shared lock row only if it is unheld
+// or expired. It is safe to call from every node on every tick.
+func tryAcquireSweeperLease(holderId string, leaseDuration time.Duration) bool {
+	now := time.Now()
+	newExpiry := now.Add(leaseDuration).Format(time.RFC3339)
+
+	lock := SweeperLock{Name: sweeperLockName}
+	existed, err := adapter.engine.Get(&lock)
+	if err != nil {
+		return false
+	}
+	if !existed {
+		lock = SweeperLock{Name: sweeperLockName, HolderId: holderId, LeaseExpiry: newExpiry}
+		_, err = adapter.engine.Insert(&lock)
+		return err == nil
+	}
+
+	expiry, err := time.Parse(time.RFC3339, lock.LeaseExpiry)
+	if err == nil && expiry.After(now) && lock.HolderId != holderId {
+		return false
+	}
+
+	affected, err := adapter.engine.Where("name = ? AND (holder_id = ? OR lease_expiry <= ?)", sweeperLockName, holderId, now.Format(time.RFC3339)).
+		Cols("holder_id", "lease_expiry").Update(&SweeperLock{HolderId: holderId, LeaseExpiry: newExpiry})
+	return err == nil && affected > 0
+}
+
+// StartMessageDestructSweeper launches the background goroutine that
+// periodically sweeps and deletes expired ephemeral messages. It is started
+// once from the object package's init.
+func StartMessageDestructSweeper() {
+	holderId := util.GetRandomName()
+
+	go func() {
+		ticker := time.NewTicker(destructSweepInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if tryAcquireSweeperLease(holderId, destructSweepInterval*3) {
+				sweepExpiredMessages()
+			}
+		}
+	}()
+}
+
+func init() {
+	StartMessageDestructSweeper()
+}
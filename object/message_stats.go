@@ -0,0 +1,341 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"time"
+
+	"github.com/casibase/casibase/object/stats"
+)
+
+const statsRollupInterval = 24 * time.Hour
+
+// rollupMessageStatsDaily computes the message_stats_daily rows for a single
+// calendar day from the raw Message table, keyed by
+// (organization, chat type, provider).
+func rollupMessageStatsDaily(date string) error {
+	dayStart, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return err
+	}
+	dayEnd := dayStart.Add(24 * time.Hour)
+
+	messages := []*Message{}
+	err = adapter.engine.Where("created_time >= ? AND created_time < ?",
+		dayStart.Format(time.RFC3339), dayEnd.Format(time.RFC3339)).Find(&messages)
+	if err != nil {
+		return err
+	}
+
+	type groupKey struct {
+		organization string
+		chatType     string
+		provider     string
+	}
+	type groupAgg struct {
+		messageCount int64
+		tokenCount   int64
+		users        map[string]bool
+		latencySum   float64
+		latencyCount int64
+	}
+	groups := map[groupKey]*groupAgg{}
+	messageById := map[string]*Message{}
+	for _, message := range messages {
+		messageById[message.Name] = message
+	}
+
+	for _, message := range messages {
+		chatType := getChatTypeForMessage(message)
+		key := groupKey{organization: message.Organization, chatType: chatType, provider: message.Provider}
+		agg, ok := groups[key]
+		if !ok {
+			agg = &groupAgg{users: map[string]bool{}}
+			groups[key] = agg
+		}
+
+		agg.messageCount++
+		agg.tokenCount += int64(message.TokenCount)
+		if message.User != "" {
+			agg.users[message.User] = true
+		}
+
+		if message.Author == "AI" && message.ReplyTo != "" {
+			if userMessage, ok := messageById[message.ReplyTo]; ok {
+				latencyMs := latencyBetween(userMessage.CreatedTime, message.CreatedTime)
+				if latencyMs >= 0 {
+					agg.latencySum += latencyMs
+					agg.latencyCount++
+				}
+			}
+		}
+	}
+
+	for key, agg := range groups {
+		avgLatency := float64(0)
+		if agg.latencyCount > 0 {
+			avgLatency = agg.latencySum / float64(agg.latencyCount)
+		}
+
+		row := &stats.MessageStatsDaily{
+			Date:            date,
+			Organization:    key.organization,
+			ChatType:        key.chatType,
+			Provider:        key.provider,
+			MessageCount:    agg.messageCount,
+			ActiveUserCount: int64(len(agg.users)),
+			TokenCount:      agg.tokenCount,
+			AvgLatencyMs:    avgLatency,
+		}
+
+		err = upsertMessageStatsDaily(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func upsertMessageStatsDaily(row *stats.MessageStatsDaily) error {
+	existing := stats.MessageStatsDaily{Date: row.Date, Organization: row.Organization, ChatType: row.ChatType, Provider: row.Provider}
+	existed, err := adapter.engine.Get(&existing)
+	if err != nil {
+		return err
+	}
+
+	if existed {
+		_, err = adapter.engine.Where("date = ? AND organization = ? AND chat_type = ? AND provider = ?",
+			row.Date, row.Organization, row.ChatType, row.Provider).
+			Cols("message_count", "active_user_count", "token_count", "avg_latency_ms").Update(row)
+		return err
+	}
+
+	_, err = adapter.engine.Insert(row)
+	return err
+}
+
+func latencyBetween(startTime string, endTime string) float64 {
+	start, err1 := time.Parse(time.RFC3339, startTime)
+	end, err2 := time.Parse(time.RFC3339, endTime)
+	if err1 != nil || err2 != nil {
+		return -1
+	}
+
+	return float64(end.Sub(start).Milliseconds())
+}
+
+// getChatTypeForMessage looks up the owning chat's Type for a message,
+// falling back to "" if the chat can no longer be found.
+func getChatTypeForMessage(message *Message) string {
+	chatId := message.Owner + "/" + message.Chat
+	chat, err := GetChat(chatId)
+	if err != nil || chat == nil {
+		return ""
+	}
+
+	return chat.Type
+}
+
+// GetMessageStats returns message counts and token counts, bucketed by
+// granularity and optionally filtered by organization and chat type. The
+// buckets' AvgLatencyMs is not computed and stays 0: neither the raw hour
+// path nor the rollup path fills it in.
+func GetMessageStats(granularity stats.Granularity, startDate string, endDate string, organization string, chatType string) ([]*stats.Bucket, error) {
+	if granularity == stats.Hour {
+		return getMessageStatsFromRaw(startDate, endDate, organization, chatType)
+	}
+
+	return getMessageStatsFromRollup(granularity, startDate, endDate, organization, chatType)
+}
+
+func getMessageStatsFromRollup(granularity stats.Granularity, startDate string, endDate string, organization string, chatType string) ([]*stats.Bucket, error) {
+	rows := []*stats.MessageStatsDaily{}
+	session := adapter.engine.Where("date >= ? AND date <= ?", startDate, endDate)
+	if organization != "" {
+		session = session.And("organization = ?", organization)
+	}
+	if chatType != "" {
+		session = session.And("chat_type = ?", chatType)
+	}
+	err := session.Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]*stats.Bucket{}
+	for _, row := range rows {
+		date, err := time.Parse("2006-01-02", row.Date)
+		if err != nil {
+			continue
+		}
+		bucketTime := stats.TruncateToBucket(date, granularity)
+
+		bucket, ok := merged[bucketTime]
+		if !ok {
+			bucket = &stats.Bucket{Time: bucketTime}
+			merged[bucketTime] = bucket
+		}
+		bucket.MessageCount += row.MessageCount
+		bucket.TokenCount += row.TokenCount
+		bucket.ActiveUserCount += row.ActiveUserCount
+	}
+
+	return sortedBuckets(merged), nil
+}
+
+// rawStatsTimeRange converts a "YYYY-MM-DD" start/end date pair into
+// RFC3339 bounds suitable for comparing against created_time, which is
+// stored as a full timestamp: a plain "<= endDate" string comparison would
+// lexicographically exclude every timestamp on endDate itself, so the end
+// bound is the start of the following day, exclusive.
+func rawStatsTimeRange(startDate string, endDate string) (string, string, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return "", "", err
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return "", "", err
+	}
+	end = end.Add(24 * time.Hour)
+
+	return start.Format(time.RFC3339), end.Format(time.RFC3339), nil
+}
+
+func getMessageStatsFromRaw(startDate string, endDate string, organization string, chatType string) ([]*stats.Bucket, error) {
+	rangeStart, rangeEnd, err := rawStatsTimeRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []*Message{}
+	session := adapter.engine.Where("created_time >= ? AND created_time < ?", rangeStart, rangeEnd)
+	if organization != "" {
+		session = session.And("organization = ?", organization)
+	}
+	err = session.Find(&messages)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]*stats.Bucket{}
+	for _, message := range messages {
+		if chatType != "" && getChatTypeForMessage(message) != chatType {
+			continue
+		}
+
+		createdTime, err := time.Parse(time.RFC3339, message.CreatedTime)
+		if err != nil {
+			continue
+		}
+		bucketTime := stats.TruncateToBucket(createdTime, stats.Hour)
+
+		bucket, ok := merged[bucketTime]
+		if !ok {
+			bucket = &stats.Bucket{Time: bucketTime}
+			merged[bucketTime] = bucket
+		}
+		bucket.MessageCount++
+		bucket.TokenCount += int64(message.TokenCount)
+	}
+
+	return sortedBuckets(merged), nil
+}
+
+// GetActiveUsers returns DAU/WAU/MAU-style unique active user counts bucketed
+// by granularity. Every granularity is computed from raw Message rows: the
+// message_stats_daily rollup only keeps a distinct-user count per day and
+// (organization, chat type, provider) group, and adding those up would count
+// a user once per day and per group instead of once per bucket.
+func GetActiveUsers(granularity stats.Granularity, startDate string, endDate string, organization string) ([]*stats.Bucket, error) {
+	return getActiveUsersFromRaw(granularity, startDate, endDate, organization)
+}
+
+func getActiveUsersFromRaw(granularity stats.Granularity, startDate string, endDate string, organization string) ([]*stats.Bucket, error) {
+	rangeStart, rangeEnd, err := rawStatsTimeRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []*Message{}
+	session := adapter.engine.Where("created_time >= ? AND created_time < ?", rangeStart, rangeEnd)
+	if organization != "" {
+		session = session.And("organization = ?", organization)
+	}
+	err = session.Find(&messages)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByBucket := map[string]map[string]bool{}
+	for _, message := range messages {
+		if message.User == "" {
+			continue
+		}
+
+		createdTime, err := time.Parse(time.RFC3339, message.CreatedTime)
+		if err != nil {
+			continue
+		}
+		bucketTime := stats.TruncateToBucket(createdTime, granularity)
+
+		users, ok := usersByBucket[bucketTime]
+		if !ok {
+			users = map[string]bool{}
+			usersByBucket[bucketTime] = users
+		}
+		users[message.User] = true
+	}
+
+	merged := map[string]*stats.Bucket{}
+	for bucketTime, users := range usersByBucket {
+		merged[bucketTime] = &stats.Bucket{Time: bucketTime, ActiveUserCount: int64(len(users))}
+	}
+
+	return sortedBuckets(merged), nil
+}
+
+func sortedBuckets(merged map[string]*stats.Bucket) []*stats.Bucket {
+	result := make([]*stats.Bucket, 0, len(merged))
+	for _, bucket := range merged {
+		result = append(result, bucket)
+	}
+	for i := 1; i < len(result); i++ {
+		for j := i; j > 0 && result[j-1].Time > result[j].Time; j-- {
+			result[j-1], result[j] = result[j], result[j-1]
+		}
+	}
+
+	return result
+}
+
+// StartStatsRollupWorker launches the daily worker that, every 24 hours
+// counted from startup, computes yesterday's message_stats_daily rows.
+func StartStatsRollupWorker() {
+	go func() {
+		ticker := time.NewTicker(statsRollupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			yesterday := time.Now().Add(-24 * time.Hour).Format("2006-01-02")
+			_ = rollupMessageStatsDaily(yesterday)
+		}
+	}()
+}
+
+func init() {
+	StartStatsRollupWorker()
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestRawStatsTimeRangeIncludesWholeEndDate(t *testing.T) {
+	rangeStart, rangeEnd, err := rawStatsTimeRange("2024-05-01", "2024-05-01")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rangeStart != "2024-05-01T00:00:00Z" {
+		t.Errorf("rangeStart = %s, want 2024-05-01T00:00:00Z", rangeStart)
+	}
+	if rangeEnd != "2024-05-02T00:00:00Z" {
+		t.Errorf("rangeEnd = %s, want 2024-05-02T00:00:00Z", rangeEnd)
+	}
+
+	// A timestamp late on the end date must fall inside [rangeStart, rangeEnd).
+	lateOnEndDate := "2024-05-01T23:59:59Z"
+	if !(lateOnEndDate >= rangeStart && lateOnEndDate < rangeEnd) {
+		t.Errorf("expected %s to fall within [%s, %s)", lateOnEndDate, rangeStart, rangeEnd)
+	}
+}
+
+func TestRawStatsTimeRangeInvalidDate(t *testing.T) {
+	if _, _, err := rawStatsTimeRange("not-a-date", "2024-05-01"); err == nil {
+		t.Fatal("expected an error for an invalid start date")
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/casibase/casibase/object/stats"
+)
+
+// GetRegistrationStats returns time-bucketed new-user registration counts,
+// optionally filtered by organization. Casibase delegates user storage to
+// Casdoor, so this reads straight from the Casdoor user list rather than a
+// local table.
+func GetRegistrationStats(granularity stats.Granularity, startDate string, endDate string, organization string) ([]*stats.Bucket, error) {
+	var users []*casdoorsdk.User
+	var err error
+	if organization != "" {
+		users, err = casdoorsdk.GetUsersByOrganization(organization)
+	} else {
+		users, err = casdoorsdk.GetUsers()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]*stats.Bucket{}
+	for _, user := range users {
+		createdTime, err := time.Parse(time.RFC3339, user.CreatedTime)
+		if err != nil {
+			continue
+		}
+		if createdTime.Format("2006-01-02") < startDate || createdTime.Format("2006-01-02") > endDate {
+			continue
+		}
+
+		bucketTime := stats.TruncateToBucket(createdTime, granularity)
+		bucket, ok := merged[bucketTime]
+		if !ok {
+			bucket = &stats.Bucket{Time: bucketTime}
+			merged[bucketTime] = bucket
+		}
+		bucket.Count++
+	}
+
+	return sortedBuckets(merged), nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/casibase/casibase/util"
+	"xorm.io/core"
+)
+
+// RemoteUser represents a fediverse actor that follows or is followed by a
+// local organization or chat actor.
+type RemoteUser struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	ActorId           string `xorm:"varchar(255) index" json:"actorId"`
+	Inbox             string `xorm:"varchar(255)" json:"inbox"`
+	SharedInbox       string `xorm:"varchar(255)" json:"sharedInbox"`
+	PreferredUsername string `xorm:"varchar(100)" json:"preferredUsername"`
+	PublicKeyPem      string `xorm:"mediumtext" json:"publicKeyPem"`
+
+	// LocalActor is the actor URL (see GetActorId) of the local organization
+	// or chat that this remote user is following.
+	LocalActor string `xorm:"varchar(100) index" json:"localActor"`
+}
+
+func GetRemoteUser(id string) (*RemoteUser, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	remoteUser := RemoteUser{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&remoteUser)
+	if err != nil {
+		return nil, err
+	}
+	if !existed {
+		return nil, nil
+	}
+
+	return &remoteUser, nil
+}
+
+func GetRemoteUserByActorId(localActor string, actorId string) (*RemoteUser, error) {
+	remoteUser := RemoteUser{LocalActor: localActor, ActorId: actorId}
+	existed, err := adapter.engine.Get(&remoteUser)
+	if err != nil {
+		return nil, err
+	}
+	if !existed {
+		return nil, nil
+	}
+
+	return &remoteUser, nil
+}
+
+// GetFollowers returns all remote actors currently following the given local
+// actor (organization or public chat).
+func GetFollowers(localActor string) ([]*RemoteUser, error) {
+	remoteUsers := []*RemoteUser{}
+	err := adapter.engine.Where("local_actor = ?", localActor).Find(&remoteUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	return remoteUsers, nil
+}
+
+// GetRemoteUserByActorIdAny looks up a remote actor regardless of which
+// local actor it follows, used to reuse an already-cached public key when
+// verifying inbound signatures.
+func GetRemoteUserByActorIdAny(actorId string) (*RemoteUser, error) {
+	remoteUser := RemoteUser{ActorId: actorId}
+	existed, err := adapter.engine.Get(&remoteUser)
+	if err != nil {
+		return nil, err
+	}
+	if !existed {
+		return nil, nil
+	}
+
+	return &remoteUser, nil
+}
+
+func AddRemoteUser(remoteUser *RemoteUser) (bool, error) {
+	affected, err := adapter.engine.Insert(remoteUser)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteRemoteUser(remoteUser *RemoteUser) (bool, error) {
+	affected, err := adapter.engine.ID(core.PK{remoteUser.Owner, remoteUser.Name}).Delete(&RemoteUser{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteRemoteUserByActorId(localActor string, actorId string) (bool, error) {
+	affected, err := adapter.engine.Where("local_actor = ? AND actor_id = ?", localActor, actorId).Delete(&RemoteUser{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (remoteUser *RemoteUser) GetId() string {
+	return fmt.Sprintf("%s/%s", remoteUser.Owner, remoteUser.Name)
+}
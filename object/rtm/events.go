@@ -0,0 +1,33 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtm
+
+// PublishMessageAdded notifies every subscriber of chat that a new message
+// was added.
+func PublishMessageAdded(chat string, message interface{}) {
+	DefaultHub().Broadcast(chat, Event{Type: "message.added", Chat: chat, Message: message})
+}
+
+// PublishMessageUpdated notifies every subscriber of chat that a message was
+// updated.
+func PublishMessageUpdated(chat string, message interface{}) {
+	DefaultHub().Broadcast(chat, Event{Type: "message.updated", Chat: chat, Message: message})
+}
+
+// PublishMessageDeleted notifies every subscriber of chat that a message was
+// deleted.
+func PublishMessageDeleted(chat string, message interface{}) {
+	DefaultHub().Broadcast(chat, Event{Type: "message.deleted", Chat: chat, Message: message})
+}
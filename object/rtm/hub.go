@@ -0,0 +1,164 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rtm is the real-time gateway that fans out chat message and
+// presence events out to every connected websocket client, similar to a
+// Slack RTM client: one long-lived socket per connected client, with
+// ping/pong keepalive and an atomic message-id counter.
+package rtm
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	pongWait   = 60 * time.Second
+	pingPeriod = (pongWait * 9) / 10
+	writeWait  = 10 * time.Second
+)
+
+// Event is a single RTM event streamed down a client's websocket.
+type Event struct {
+	Id      uint64      `json:"id"`
+	Type    string      `json:"type"`
+	Chat    string      `json:"chat,omitempty"`
+	User    string      `json:"user,omitempty"`
+	Message interface{} `json:"message,omitempty"`
+}
+
+// Client is one connected websocket, subscribed to a single chat.
+type Client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	user string
+	chat string
+	send chan Event
+}
+
+// Hub maintains the per-user pool of connected clients and fans out events
+// to the subscribers of each chat.
+type Hub struct {
+	mutex         sync.RWMutex
+	clientsByChat map[string]map[*Client]bool
+	nextEventId   uint64
+}
+
+var defaultHub = NewHub()
+
+// DefaultHub returns the process-wide RTM hub used by the ApiController.
+func DefaultHub() *Hub {
+	return defaultHub
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clientsByChat: map[string]map[*Client]bool{},
+	}
+}
+
+// Register starts serving a newly-upgraded websocket connection as a
+// subscriber of the given chat, blocking until the connection closes.
+func (h *Hub) Register(conn *websocket.Conn, user string, chat string) {
+	client := &Client{hub: h, conn: conn, user: user, chat: chat, send: make(chan Event, 64)}
+
+	h.mutex.Lock()
+	if h.clientsByChat[chat] == nil {
+		h.clientsByChat[chat] = map[*Client]bool{}
+	}
+	h.clientsByChat[chat][client] = true
+	h.mutex.Unlock()
+
+	h.Broadcast(chat, Event{Type: "user.online", Chat: chat, User: user})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		client.writePump()
+	}()
+	go func() {
+		defer wg.Done()
+		client.readPump()
+	}()
+	wg.Wait()
+
+	h.mutex.Lock()
+	delete(h.clientsByChat[chat], client)
+	if len(h.clientsByChat[chat]) == 0 {
+		delete(h.clientsByChat, chat)
+	}
+	h.mutex.Unlock()
+}
+
+// Broadcast delivers an event to every client currently subscribed to chat.
+func (h *Hub) Broadcast(chat string, event Event) {
+	event.Id = atomic.AddUint64(&h.nextEventId, 1)
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for client := range h.clientsByChat[chat] {
+		select {
+		case client.send <- event:
+		default:
+			// Slow consumer: drop the event rather than blocking the fan-out.
+		}
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) readPump() {
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		messageType, payload, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType == websocket.TextMessage && string(payload) == "typing" {
+			c.hub.Broadcast(c.chat, Event{Type: "user.typing", Chat: c.chat, User: c.user})
+		}
+	}
+}
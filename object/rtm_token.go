@@ -0,0 +1,76 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"sync"
+	"time"
+
+	"github.com/casibase/casibase/util"
+)
+
+const rtmTokenTtl = 60 * time.Second
+
+type rtmTokenEntry struct {
+	user      string
+	expiresAt time.Time
+}
+
+var (
+	rtmTokenMutex sync.Mutex
+	rtmTokens     = map[string]rtmTokenEntry{}
+)
+
+// GetRtmToken mints a short-lived token a client can use to authenticate its
+// websocket upgrade request when it has no session cookie (e.g. a
+// cross-origin frontend).
+func GetRtmToken(user string) string {
+	token := util.GetRandomName()
+
+	rtmTokenMutex.Lock()
+	purgeExpiredRtmTokensLocked()
+	rtmTokens[token] = rtmTokenEntry{user: user, expiresAt: time.Now().Add(rtmTokenTtl)}
+	rtmTokenMutex.Unlock()
+
+	return token
+}
+
+// purgeExpiredRtmTokensLocked drops every expired, unused token. Tokens that
+// expire without ever being consumed by VerifyRtmToken would otherwise never
+// be removed, so GetRtmToken sweeps them lazily on every mint instead of
+// running a dedicated background goroutine. Callers must hold rtmTokenMutex.
+func purgeExpiredRtmTokensLocked() {
+	now := time.Now()
+	for token, entry := range rtmTokens {
+		if now.After(entry.expiresAt) {
+			delete(rtmTokens, token)
+		}
+	}
+}
+
+// VerifyRtmToken consumes a single-use RTM token, returning the user it was
+// issued for, or "" if the token is missing, expired, or already used.
+func VerifyRtmToken(token string) string {
+	rtmTokenMutex.Lock()
+	defer rtmTokenMutex.Unlock()
+
+	entry, ok := rtmTokens[token]
+	delete(rtmTokens, token)
+	if !ok || time.Now().After(entry.expiresAt) {
+		return ""
+	}
+
+	return entry.user
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetRtmTokenPurgesExpiredUnusedTokens(t *testing.T) {
+	rtmTokenMutex.Lock()
+	rtmTokens = map[string]rtmTokenEntry{
+		"expired-1": {user: "alice", expiresAt: time.Now().Add(-time.Minute)},
+		"expired-2": {user: "bob", expiresAt: time.Now().Add(-time.Second)},
+		"fresh":     {user: "carol", expiresAt: time.Now().Add(time.Minute)},
+	}
+	rtmTokenMutex.Unlock()
+
+	GetRtmToken("dave")
+
+	rtmTokenMutex.Lock()
+	defer rtmTokenMutex.Unlock()
+
+	if _, ok := rtmTokens["expired-1"]; ok {
+		t.Error("expected expired-1 to be purged")
+	}
+	if _, ok := rtmTokens["expired-2"]; ok {
+		t.Error("expected expired-2 to be purged")
+	}
+	if _, ok := rtmTokens["fresh"]; !ok {
+		t.Error("expected fresh token to survive the purge")
+	}
+}
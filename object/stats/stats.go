@@ -0,0 +1,114 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stats computes usage and active-user analytics for messages and
+// chats. Hour-bucketed message stats and all active-user counts read Message
+// rows directly; day/week/month message stats read the message_stats_daily
+// rollup table so their cost is O(buckets) instead of O(messages).
+package stats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Bucket is one time-bucketed row of a stats query result.
+type Bucket struct {
+	Time            string  `json:"time"`
+	Organization    string  `json:"organization,omitempty"`
+	ChatType        string  `json:"chatType,omitempty"`
+	Provider        string  `json:"provider,omitempty"`
+	MessageCount    int64   `json:"messageCount"`
+	ActiveUserCount int64   `json:"activeUserCount"`
+	TokenCount      int64   `json:"tokenCount"`
+	AvgLatencyMs    float64 `json:"avgLatencyMs"`
+	// Count is a generic counter used by stats that aren't about messages,
+	// e.g. GetRegistrationStats.
+	Count int64 `json:"count,omitempty"`
+}
+
+// MessageStatsDaily is the nightly rollup table. One row per
+// (date, organization, chatType, provider) tuple.
+type MessageStatsDaily struct {
+	Date         string `xorm:"varchar(10) notnull pk" json:"date"`
+	Organization string `xorm:"varchar(100) notnull pk" json:"organization"`
+	ChatType     string `xorm:"varchar(100) notnull pk" json:"chatType"`
+	Provider     string `xorm:"varchar(100) notnull pk" json:"provider"`
+
+	MessageCount    int64   `xorm:"bigint" json:"messageCount"`
+	ActiveUserCount int64   `xorm:"bigint" json:"activeUserCount"`
+	TokenCount      int64   `xorm:"bigint" json:"tokenCount"`
+	AvgLatencyMs    float64 `xorm:"double" json:"avgLatencyMs"`
+}
+
+// Granularity is the time bucket a stats query aggregates by.
+type Granularity string
+
+const (
+	Hour  Granularity = "hour"
+	Day   Granularity = "day"
+	Week  Granularity = "week"
+	Month Granularity = "month"
+)
+
+// TruncateToBucket returns the bucket start time label for t at the given
+// granularity, e.g. "2024-05-01" for Day, "2024-W18" for Week.
+func TruncateToBucket(t time.Time, granularity Granularity) string {
+	switch granularity {
+	case Hour:
+		return t.Format("2006-01-02T15")
+	case Week:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Month:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// ToCsv renders a slice of Buckets as CSV, used by the *.csv variant of
+// every stats endpoint.
+func ToCsv(buckets []*Bucket) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	err := w.Write([]string{"time", "organization", "chatType", "provider", "messageCount", "activeUserCount", "tokenCount", "avgLatencyMs", "count"})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range buckets {
+		err = w.Write([]string{
+			b.Time,
+			b.Organization,
+			b.ChatType,
+			b.Provider,
+			strconv.FormatInt(b.MessageCount, 10),
+			strconv.FormatInt(b.ActiveUserCount, 10),
+			strconv.FormatInt(b.TokenCount, 10),
+			strconv.FormatFloat(b.AvgLatencyMs, 'f', 2, 64),
+			strconv.FormatInt(b.Count, 10),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
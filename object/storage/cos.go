@@ -0,0 +1,75 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+type cosStore struct {
+	client *cos.Client
+	bucket string
+}
+
+func newCosStore(providerConfig *ProviderConfig) (ObjectStore, error) {
+	bucketUrl, err := url.Parse(fmt.Sprintf("https://%s.cos.%s.myqcloud.com", providerConfig.Bucket, providerConfig.Region))
+	if err != nil {
+		return nil, err
+	}
+
+	client := cos.NewClient(&cos.BaseURL{BucketURL: bucketUrl}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  providerConfig.AccessKeyId,
+			SecretKey: providerConfig.AccessKeySecret,
+		},
+	})
+
+	return &cosStore{client: client, bucket: providerConfig.Bucket}, nil
+}
+
+func (s *cosStore) Bucket() string {
+	return s.bucket
+}
+
+func (s *cosStore) GetPresignedPutUrl(key string, contentType string, expiry time.Duration) (string, error) {
+	u, err := s.client.Object.GetPresignedURL(context.Background(), http.MethodPut, key,
+		s.client.GetCredential().SecretID, s.client.GetCredential().SecretKey, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func (s *cosStore) GetPresignedGetUrl(key string, expiry time.Duration) (string, error) {
+	u, err := s.client.Object.GetPresignedURL(context.Background(), http.MethodGet, key,
+		s.client.GetCredential().SecretID, s.client.GetCredential().SecretKey, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func (s *cosStore) Delete(key string) error {
+	_, err := s.client.Object.Delete(context.Background(), key)
+	return err
+}
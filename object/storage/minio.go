@@ -0,0 +1,69 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type minioStore struct {
+	client *minio.Client
+	bucket string
+}
+
+func newMinioStore(config *ProviderConfig) (ObjectStore, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKeyId, config.AccessKeySecret, ""),
+		Secure: config.UseSsl,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &minioStore{client: client, bucket: config.Bucket}, nil
+}
+
+func (s *minioStore) Bucket() string {
+	return s.bucket
+}
+
+func (s *minioStore) GetPresignedPutUrl(key string, contentType string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedPutObject(context.Background(), s.bucket, key, expiry)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func (s *minioStore) GetPresignedGetUrl(key string, expiry time.Duration) (string, error) {
+	reqParams := url.Values{}
+
+	u, err := s.client.PresignedGetObject(context.Background(), s.bucket, key, expiry, reqParams)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func (s *minioStore) Delete(key string) error {
+	return s.client.RemoveObject(context.Background(), s.bucket, key, minio.RemoveObjectOptions{})
+}
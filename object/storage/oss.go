@@ -0,0 +1,56 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+type ossStore struct {
+	bucket    *oss.Bucket
+	bucketStr string
+}
+
+func newOssStore(providerConfig *ProviderConfig) (ObjectStore, error) {
+	client, err := oss.New(providerConfig.Endpoint, providerConfig.AccessKeyId, providerConfig.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := client.Bucket(providerConfig.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ossStore{bucket: bucket, bucketStr: providerConfig.Bucket}, nil
+}
+
+func (s *ossStore) Bucket() string {
+	return s.bucketStr
+}
+
+func (s *ossStore) GetPresignedPutUrl(key string, contentType string, expiry time.Duration) (string, error) {
+	return s.bucket.SignURL(key, oss.HTTPPut, int64(expiry.Seconds()), oss.ContentType(contentType))
+}
+
+func (s *ossStore) GetPresignedGetUrl(key string, expiry time.Duration) (string, error) {
+	return s.bucket.SignURL(key, oss.HTTPGet, int64(expiry.Seconds()))
+}
+
+func (s *ossStore) Delete(key string) error {
+	return s.bucket.DeleteObject(key)
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+
+	"github.com/casibase/casibase/conf"
+	"github.com/casibase/casibase/object/storage"
+)
+
+// GetAttachmentObjectStore builds the ObjectStore for chat message
+// attachments from the admin-configured provider, so switching between
+// MinIO, AWS S3, Tencent COS and Aliyun OSS is a config change only.
+func GetAttachmentObjectStore() (storage.ObjectStore, error) {
+	config := &storage.ProviderConfig{
+		Type:            conf.GetConfigString("attachmentStorageProvider"),
+		Endpoint:        conf.GetConfigString("attachmentStorageEndpoint"),
+		Region:          conf.GetConfigString("attachmentStorageRegion"),
+		Bucket:          conf.GetConfigString("attachmentStorageBucket"),
+		AccessKeyId:     conf.GetConfigString("attachmentStorageAccessKeyId"),
+		AccessKeySecret: conf.GetConfigString("attachmentStorageAccessKeySecret"),
+		UseSsl:          conf.GetConfigString("attachmentStorageUseSsl") == "true",
+	}
+
+	return storage.NewObjectStore(config)
+}
+
+// RefineAttachmentFileName rewrites a message's generalized file metadata
+// JSON ("{bucket, key, contentType, size, etag}") into a signed GET URL, the
+// same way RefineMessageFiles already rewrites legacy local file names into
+// origin-qualified URLs. Non-JSON / legacy file names are returned as-is so
+// existing messages keep working unchanged.
+func RefineAttachmentFileName(fileName string) (string, error) {
+	var fileInfo storage.FileInfo
+	err := json.Unmarshal([]byte(fileName), &fileInfo)
+	if err != nil || fileInfo.Key == "" {
+		return fileName, nil
+	}
+
+	store, err := GetAttachmentObjectStore()
+	if err != nil {
+		return "", err
+	}
+
+	return store.GetPresignedGetUrl(fileInfo.Key, storage.DefaultPresignExpiry)
+}